@@ -0,0 +1,45 @@
+package flutter
+
+import "github.com/go-gl/glfw/v3.2/glfw"
+
+// KeyboardLayout resolves the character a physical key produces under the
+// user's active keyboard layout, so keybindings and the LogicalKeyboardKey
+// reported to Dart stay correct on non-QWERTY layouts (Dvorak, AZERTY, ...).
+// Implementations live in keyboard_layout_linux.go, keyboard_layout_darwin.go
+// and keyboard_layout_windows.go, backed respectively by libxkbcommon, the
+// macOS Text Input Sources API and the Win32 keyboard layout APIs.
+type KeyboardLayout interface {
+	// LogicalKey returns the rune scancode produces under the current
+	// layout, or 0 if it doesn't produce a printable character.
+	LogicalKey(scancode int) rune
+
+	// Close releases any native resources held by the layout. Must be
+	// called once the layout is no longer used, e.g. before replacing it
+	// with a freshly resolved one on keyboard layout change.
+	Close()
+}
+
+// layoutCandidateKeys are the physical keys scanned by resolveShortcutKey to
+// find which one currently produces a given shortcut character.
+var layoutCandidateKeys = []glfw.Key{
+	glfw.KeyA, glfw.KeyB, glfw.KeyC, glfw.KeyD, glfw.KeyE, glfw.KeyF, glfw.KeyG,
+	glfw.KeyH, glfw.KeyI, glfw.KeyJ, glfw.KeyK, glfw.KeyL, glfw.KeyM, glfw.KeyN,
+	glfw.KeyO, glfw.KeyP, glfw.KeyQ, glfw.KeyR, glfw.KeyS, glfw.KeyT, glfw.KeyU,
+	glfw.KeyV, glfw.KeyW, glfw.KeyX, glfw.KeyY, glfw.KeyZ,
+}
+
+// resolveShortcutKey finds the physical key that layout currently maps to
+// want (e.g. 'a' for select-all), falling back to fallback (the key that
+// produces want on a US QWERTY layout) when layout is nil or no candidate
+// key matches.
+func resolveShortcutKey(layout KeyboardLayout, want rune, fallback glfw.Key) glfw.Key {
+	if layout == nil {
+		return fallback
+	}
+	for _, key := range layoutCandidateKeys {
+		if layout.LogicalKey(glfw.GetKeyScancode(key)) == want {
+			return key
+		}
+	}
+	return fallback
+}
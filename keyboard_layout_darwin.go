@@ -0,0 +1,60 @@
+// +build darwin
+
+package flutter
+
+/*
+#cgo LDFLAGS: -framework Carbon
+#include <Carbon/Carbon.h>
+
+static UInt32 go_flutter_translate_key(UInt16 keyCode, UniChar *out, UniCharCount *outLength) {
+	TISInputSourceRef source = TISCopyCurrentKeyboardLayoutInputSource();
+	if (source == NULL) {
+		return 1;
+	}
+
+	CFDataRef layoutData = (CFDataRef)TISGetInputSourceProperty(source, kTISPropertyUnicodeKeyLayoutData);
+	if (layoutData == NULL) {
+		CFRelease(source);
+		return 1;
+	}
+
+	const UCKeyboardLayout *layout = (const UCKeyboardLayout *)CFDataGetBytePtr(layoutData);
+	UInt32 deadKeyState = 0;
+	OSStatus status = UCKeyTranslate(layout, keyCode, kUCKeyActionDown, 0,
+		LMGetKbdType(), kUCKeyTranslateNoDeadKeysBit, &deadKeyState, 4, outLength, out);
+
+	CFRelease(source);
+	return status == noErr ? 0 : 1;
+}
+*/
+import "C"
+
+// tisKeyboardLayout resolves scancodes (macOS virtual keycodes) against the
+// currently selected Text Input Source using TISCopyCurrentKeyboardLayoutInputSource
+// and UCKeyTranslate.
+type tisKeyboardLayout struct{}
+
+var _ KeyboardLayout = tisKeyboardLayout{}
+
+// newKeyboardLayout builds the platform KeyboardLayout used by
+// keyeventPlugin.
+func newKeyboardLayout() KeyboardLayout {
+	return tisKeyboardLayout{}
+}
+
+// Close is a no-op: tisKeyboardLayout holds no native resources, it queries
+// the active Text Input Source fresh on every call.
+func (tisKeyboardLayout) Close() {}
+
+// LogicalKey translates a macOS virtual keycode to the rune the active Text
+// Input Source produces for it.
+func (tisKeyboardLayout) LogicalKey(scancode int) rune {
+	var out [4]C.UniChar
+	var outLength C.UniCharCount
+
+	if C.go_flutter_translate_key(C.UInt16(scancode), &out[0], &outLength) != 0 || outLength == 0 {
+		return 0
+	}
+
+	return rune(out[0])
+}
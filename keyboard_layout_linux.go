@@ -0,0 +1,119 @@
+// +build linux
+
+package flutter
+
+/*
+#cgo pkg-config: xkbcommon xkbcommon-x11 xcb
+#include <xkbcommon/xkbcommon.h>
+#include <xkbcommon/xkbcommon-x11.h>
+#include <xcb/xcb.h>
+#include <stdlib.h>
+
+static struct xkb_context *go_flutter_xkb_new_context() {
+	return xkb_context_new(XKB_CONTEXT_NO_FLAGS);
+}
+*/
+import "C"
+
+import (
+	"unicode/utf8"
+	"unsafe"
+)
+
+// xkbKeyboardLayout resolves scancodes against the keymap the X server is
+// actually using, via libxkbcommon-x11. Going through the X11 core keyboard
+// device (rather than compiling a keymap from XKB_DEFAULT_* env vars, which
+// desktop environments like GNOME/KDE don't set when the user switches
+// layout through their settings UI) is what makes this track the layout the
+// user actually has active.
+type xkbKeyboardLayout struct {
+	conn    *C.xcb_connection_t
+	context *C.struct_xkb_context
+	keymap  *C.struct_xkb_keymap
+	state   *C.struct_xkb_state
+}
+
+var _ KeyboardLayout = &xkbKeyboardLayout{}
+
+// newKeyboardLayout builds the platform KeyboardLayout used by
+// keyeventPlugin. Returns nil (falling back to the static GLFW keysym table)
+// if connecting to the X server or compiling its active keymap fails.
+func newKeyboardLayout() KeyboardLayout {
+	context := C.go_flutter_xkb_new_context()
+	if context == nil {
+		return nil
+	}
+
+	conn := C.xcb_connect(nil, nil)
+	if conn == nil || C.xcb_connection_has_error(conn) != 0 {
+		C.xkb_context_unref(context)
+		return nil
+	}
+
+	deviceID := C.xkb_x11_get_core_keyboard_device_id(conn)
+	if deviceID == -1 {
+		C.xcb_disconnect(conn)
+		C.xkb_context_unref(context)
+		return nil
+	}
+
+	keymap := C.xkb_x11_keymap_new_from_device(context, conn, deviceID, C.XKB_KEYMAP_COMPILE_NO_FLAGS)
+	if keymap == nil {
+		C.xcb_disconnect(conn)
+		C.xkb_context_unref(context)
+		return nil
+	}
+
+	state := C.xkb_x11_state_new_from_device(keymap, conn, deviceID)
+	if state == nil {
+		C.xkb_keymap_unref(keymap)
+		C.xcb_disconnect(conn)
+		C.xkb_context_unref(context)
+		return nil
+	}
+
+	return &xkbKeyboardLayout{conn: conn, context: context, keymap: keymap, state: state}
+}
+
+// Close releases the native XKB/xcb handles held by l. Safe to call on a nil
+// receiver. newKeyboardLayout's failure paths return a nil KeyboardLayout
+// interface, not a nil *xkbKeyboardLayout, so callers check for nil first.
+func (l *xkbKeyboardLayout) Close() {
+	if l == nil {
+		return
+	}
+	if l.state != nil {
+		C.xkb_state_unref(l.state)
+	}
+	if l.keymap != nil {
+		C.xkb_keymap_unref(l.keymap)
+	}
+	if l.context != nil {
+		C.xkb_context_unref(l.context)
+	}
+	if l.conn != nil {
+		C.xcb_disconnect(l.conn)
+	}
+}
+
+// LogicalKey translates an X11/evdev scancode to the rune the active XKB
+// layout produces for it. GLFW scancodes on Linux are X11 keycodes, which
+// are evdev keycodes offset by 8, matching what xkb_state_key_get_utf8
+// expects.
+func (l *xkbKeyboardLayout) LogicalKey(scancode int) rune {
+	if l == nil || l.state == nil {
+		return 0
+	}
+
+	var buf [8]C.char
+	n := C.xkb_state_key_get_utf8(l.state, C.xkb_keycode_t(scancode), &buf[0], C.size_t(len(buf)))
+	if n <= 0 {
+		return 0
+	}
+
+	r, _ := utf8.DecodeRune(C.GoBytes(unsafe.Pointer(&buf[0]), n))
+	if r == utf8.RuneError {
+		return 0
+	}
+	return r
+}
@@ -0,0 +1,63 @@
+// +build windows
+
+package flutter
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32                  = syscall.NewLazyDLL("user32.dll")
+	procGetKeyboardLayout   = user32.NewProc("GetKeyboardLayout")
+	procMapVirtualKeyExW    = user32.NewProc("MapVirtualKeyExW")
+	procToUnicodeEx         = user32.NewProc("ToUnicodeEx")
+	procGetKeyboardState    = user32.NewProc("GetKeyboardState")
+	procGetWindowThreadPid  = user32.NewProc("GetWindowThreadProcessId")
+	procGetForegroundWindow = user32.NewProc("GetForegroundWindow")
+)
+
+const mapvkVscToVk = 1 // MAPVK_VSC_TO_VK
+
+// win32KeyboardLayout resolves scancodes against the layout loaded for the
+// foreground window's thread, using GetKeyboardLayout, MapVirtualKeyEx and
+// ToUnicodeEx.
+type win32KeyboardLayout struct{}
+
+var _ KeyboardLayout = win32KeyboardLayout{}
+
+// newKeyboardLayout builds the platform KeyboardLayout used by
+// keyeventPlugin.
+func newKeyboardLayout() KeyboardLayout {
+	return win32KeyboardLayout{}
+}
+
+// Close is a no-op: win32KeyboardLayout holds no native resources, it
+// queries the foreground window's layout fresh on every call.
+func (win32KeyboardLayout) Close() {}
+
+// LogicalKey translates a Win32 scancode to the rune the active keyboard
+// layout produces for it.
+func (win32KeyboardLayout) LogicalKey(scancode int) rune {
+	hwnd, _, _ := procGetForegroundWindow.Call()
+	var pid uint32
+	threadID, _, _ := procGetWindowThreadPid.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+	hkl, _, _ := procGetKeyboardLayout.Call(threadID)
+
+	vk, _, _ := procMapVirtualKeyExW.Call(uintptr(scancode), mapvkVscToVk, hkl)
+	if vk == 0 {
+		return 0
+	}
+
+	var keyboardState [256]byte
+	procGetKeyboardState.Call(uintptr(unsafe.Pointer(&keyboardState[0])))
+
+	var out [4]uint16
+	n, _, _ := procToUnicodeEx.Call(vk, uintptr(scancode), uintptr(unsafe.Pointer(&keyboardState[0])),
+		uintptr(unsafe.Pointer(&out[0])), uintptr(len(out)), 0, hkl)
+	if int32(n) <= 0 {
+		return 0
+	}
+
+	return rune(out[0])
+}
@@ -0,0 +1,26 @@
+package flutter
+
+import "github.com/go-gl/glfw/v3.2/glfw"
+
+// KeyboardShortcuts holds the physical keys currently bound to the standard
+// text-editing shortcuts.
+type KeyboardShortcuts struct {
+	SelectAll glfw.Key
+	Copy      glfw.Key
+	Cut       glfw.Key
+	Paste     glfw.Key
+}
+
+// NewKeyboardShortcuts resolves the select-all/copy/cut/paste shortcuts
+// against the active keyboard layout, so e.g. Ctrl+C still triggers Copy on
+// an AZERTY or Dvorak layout where the physical key isn't where it would be
+// on a US QWERTY layout. layout may be nil, in which case the shortcuts fall
+// back to the keys that produce a/c/x/v on a US QWERTY layout.
+func NewKeyboardShortcuts(layout KeyboardLayout) KeyboardShortcuts {
+	return KeyboardShortcuts{
+		SelectAll: resolveShortcutKey(layout, 'a', glfw.KeyA),
+		Copy:      resolveShortcutKey(layout, 'c', glfw.KeyC),
+		Cut:       resolveShortcutKey(layout, 'x', glfw.KeyX),
+		Paste:     resolveShortcutKey(layout, 'v', glfw.KeyV),
+	}
+}
@@ -0,0 +1,302 @@
+package flutter
+
+import (
+	"fmt"
+
+	"github.com/go-flutter-desktop/go-flutter/plugin"
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// keyeventPlugin translates GLFW key events into the platform-native
+// payload expected by the framework's RawKeyEvent decoders
+// (RawKeyEventDataLinux/Macos/Windows). It replaces the previous approach of
+// always reporting a "keymap":"android" payload built from GLFW keycodes,
+// which produced the wrong LogicalKeyboardKey on every desktop platform.
+//
+// The per-platform translation (GLFW keycode/scancode -> native keycode,
+// modifier bits, ...) lives in keyevent_linux.go, keyevent_darwin.go and
+// keyevent_windows.go so it can be built and tested independently of
+// textinputPlugin.
+type keyeventPlugin struct {
+	messenger plugin.BinaryMessenger
+	window    *glfw.Window
+	channel   *plugin.BasicMessageChannel
+
+	// sendFunc is how flush emits an event once built; it's a field (rather
+	// than calling p.channel.Send directly) so keyevent_test.go can verify
+	// the pending/flush ordering logic without a real BinaryMessenger.
+	// InitPlugin points it at p.channel.Send.
+	sendFunc func(message interface{})
+
+	// pending holds the most recent keydown/keyup event whose GLFW char
+	// callback (if any) hasn't fired yet. GLFW always calls the key callback
+	// before the char callback for the same keypress, so textinputPlugin's
+	// glfwKeyCallback can't attach the typed character to an event as it
+	// sends it: send queues the event here instead, and recordChar attaches
+	// the character and flushes it once the char callback runs. Any event
+	// still pending when the next one is queued is flushed without a
+	// character, since GLFW only raises a char callback for the keypress
+	// that immediately precedes it.
+	pending *pendingKeyEvent
+
+	// lastChar is stored by recordChar when no event is pending (e.g. a char
+	// callback firing with no matching key callback), and consumed by flush,
+	// which attaches it to the next event it sends without a character.
+	lastChar rune
+
+	// polledMods holds the hardware modifier state last observed by
+	// glfwFocusCallback, for the bits send hasn't since heard a real
+	// press/release for. It is folded into every outgoing event to cover
+	// the case where a modifier was pressed before the window regained
+	// focus, so GLFW's per-event mods never reported it. send clears or
+	// sets the bit for a modifier key the moment it sees a real event for
+	// it, so a stale bit doesn't keep getting OR'd in after that modifier's
+	// real release.
+	polledMods glfw.ModifierKey
+
+	// capsLockOn/numLockOn track whether Caps Lock/Num Lock are toggled on.
+	// GLFW 3.2 has no API to query the lock LED state directly, so it is
+	// inferred by flipping the bool every time the physical key is pressed,
+	// mirroring how the OS itself toggles the lock.
+	capsLockOn bool
+	numLockOn  bool
+
+	// pressedModifierKeys tracks which of modifierKeys are currently down,
+	// kept up to date both by glfwFocusCallback's resync poll and by send
+	// observing a real press/release. glfwFocusCallback diffs its poll
+	// against this map to synthesize the keydown/keyup events GLFW
+	// swallowed while the window was unfocused; keeping it current from the
+	// normal event path too means that diff only ever fires for changes
+	// that happened while unfocused, not ones already reported for real.
+	pressedModifierKeys map[glfw.Key]bool
+
+	// layout resolves the logical character a scancode produces under the
+	// active keyboard layout. May be nil if the platform-specific backend
+	// failed to initialize, in which case translation falls back to the
+	// static GLFW keysym tables.
+	layout KeyboardLayout
+
+	// onLayoutChanged, if set, is invoked after layout is refreshed so
+	// dependents (textinputPlugin's KeyboardShortcuts) can re-resolve
+	// their physical keys.
+	onLayoutChanged func()
+}
+
+// pendingKeyEvent is a keydown/keyup event queued by send, waiting to be
+// flushed once it's known whether a char callback follows it.
+type pendingKeyEvent struct {
+	key      glfw.Key
+	scancode int
+	typeKey  string
+	mods     glfw.ModifierKey
+}
+
+// modifierKeys are the physical keys glfwFocusCallback polls via
+// window.GetKey to resynchronize RawKeyboard.keysPressed on focus.
+var modifierKeys = []glfw.Key{
+	glfw.KeyLeftShift, glfw.KeyRightShift,
+	glfw.KeyLeftControl, glfw.KeyRightControl,
+	glfw.KeyLeftAlt, glfw.KeyRightAlt,
+	glfw.KeyLeftSuper, glfw.KeyRightSuper,
+	glfw.KeyCapsLock, glfw.KeyNumLock,
+}
+
+// modifierBit returns the glfw.ModifierKey bit key contributes to, and
+// whether it has one. Caps Lock/Num Lock are in modifierKeys (for
+// keysPressed resync) but aren't part of GLFW's modifier bitmask.
+func modifierBit(key glfw.Key) (glfw.ModifierKey, bool) {
+	switch key {
+	case glfw.KeyLeftShift, glfw.KeyRightShift:
+		return glfw.ModShift, true
+	case glfw.KeyLeftControl, glfw.KeyRightControl:
+		return glfw.ModControl, true
+	case glfw.KeyLeftAlt, glfw.KeyRightAlt:
+		return glfw.ModAlt, true
+	case glfw.KeyLeftSuper, glfw.KeyRightSuper:
+		return glfw.ModSuper, true
+	default:
+		return 0, false
+	}
+}
+
+// isModifierKey reports whether key is one of modifierKeys.
+func isModifierKey(key glfw.Key) bool {
+	for _, mk := range modifierKeys {
+		if mk == key {
+			return true
+		}
+	}
+	return false
+}
+
+var _ Plugin = &keyeventPlugin{}     // compile-time type check
+var _ PluginGLFW = &keyeventPlugin{} // compile-time type check
+
+func (p *keyeventPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
+	p.messenger = messenger
+	p.channel = plugin.NewBasicMessageChannel(p.messenger, keyEventChannelName, plugin.JSONMessageCodec{})
+	p.sendFunc = p.channel.Send
+	p.pressedModifierKeys = make(map[glfw.Key]bool, len(modifierKeys))
+	return nil
+}
+
+func (p *keyeventPlugin) InitPluginGLFW(window *glfw.Window) error {
+	p.window = window
+	p.layout = newKeyboardLayout()
+	window.SetFocusCallback(p.glfwFocusCallback)
+	return nil
+}
+
+// glfwFocusCallback polls the real hardware modifier state whenever the
+// window regains focus. GLFW only ever reports key events while the window
+// is focused, so a modifier held down across an Alt-Tab is otherwise never
+// observed, leaving RawKeyboard.keysPressed out of sync on the Dart side.
+// Regaining focus is also used as the trigger to refresh the resolved
+// keyboard layout, since GLFW has no dedicated layout-change event.
+func (p *keyeventPlugin) glfwFocusCallback(w *glfw.Window, focused bool) {
+	if !focused {
+		return
+	}
+
+	newLayout := newKeyboardLayout()
+	if p.layout != nil {
+		p.layout.Close()
+	}
+	p.layout = newLayout
+	if p.onLayoutChanged != nil {
+		p.onLayoutChanged()
+	}
+
+	var mods glfw.ModifierKey
+	if w.GetKey(glfw.KeyLeftControl) == glfw.Press || w.GetKey(glfw.KeyRightControl) == glfw.Press {
+		mods |= glfw.ModControl
+	}
+	if w.GetKey(glfw.KeyLeftShift) == glfw.Press || w.GetKey(glfw.KeyRightShift) == glfw.Press {
+		mods |= glfw.ModShift
+	}
+	if w.GetKey(glfw.KeyLeftAlt) == glfw.Press || w.GetKey(glfw.KeyRightAlt) == glfw.Press {
+		mods |= glfw.ModAlt
+	}
+	if w.GetKey(glfw.KeyLeftSuper) == glfw.Press || w.GetKey(glfw.KeyRightSuper) == glfw.Press {
+		mods |= glfw.ModSuper
+	}
+	p.polledMods = mods
+
+	for _, key := range modifierKeys {
+		pressed := w.GetKey(key) == glfw.Press
+		if pressed == p.pressedModifierKeys[key] {
+			continue
+		}
+		p.pressedModifierKeys[key] = pressed
+		action := glfw.Release
+		if pressed {
+			action = glfw.Press
+		}
+		// Queue directly rather than going through send: this is a
+		// synthesized resync of physical key state, not a real Caps
+		// Lock/Num Lock keypress, and must not flip capsLockOn/numLockOn.
+		p.queue(key, 0, action, mods)
+	}
+}
+
+// recordChar remembers the most recently typed rune. If a keydown event is
+// still waiting on its char callback, char is attached to it and the event
+// is flushed immediately; otherwise it's kept in lastChar, which flush
+// attaches to the next event it sends without a character.
+func (p *keyeventPlugin) recordChar(char rune) {
+	if p.pending != nil {
+		p.flush(char)
+		return
+	}
+	p.lastChar = char
+}
+
+// send builds the keymap-specific event for key/scancode/mods and queues it
+// on the flutter/keyevent channel, and infers the Caps Lock/Num Lock toggle
+// state along the way. GLFW always calls the key callback before the char
+// callback for the same keypress, so a keydown event isn't flushed
+// immediately: it's queued here and only sent once recordChar attaches its
+// character (or, for keys with no char callback, once the next event
+// arrives). A keyup is flushed right away, since it never gets a char
+// callback.
+func (p *keyeventPlugin) send(key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	if action == glfw.Press {
+		switch key {
+		case glfw.KeyCapsLock:
+			p.capsLockOn = !p.capsLockOn
+		case glfw.KeyNumLock:
+			p.numLockOn = !p.numLockOn
+		}
+	}
+
+	// A real press/release of a modifier key is authoritative: update the
+	// polled baseline so a bit set before this key's own release doesn't
+	// keep getting OR'd into every event after that release.
+	if bit, ok := modifierBit(key); ok {
+		switch action {
+		case glfw.Press:
+			p.polledMods |= bit
+		case glfw.Release:
+			p.polledMods &^= bit
+		}
+	}
+
+	// Keep pressedModifierKeys current from the normal event path too, so
+	// glfwFocusCallback's resync diff only fires for state changes GLFW
+	// actually swallowed while unfocused, not ones already reported here.
+	if isModifierKey(key) {
+		switch action {
+		case glfw.Press:
+			p.pressedModifierKeys[key] = true
+		case glfw.Release:
+			p.pressedModifierKeys[key] = false
+		}
+	}
+
+	p.queue(key, scancode, action, mods)
+}
+
+// queue flushes any previously pending event (without a character, since no
+// char callback followed it before this next event arrived) and, for a
+// keydown, queues the new event to wait for recordChar. A keyup never gets a
+// char callback, so it's flushed immediately.
+func (p *keyeventPlugin) queue(key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	var typeKey string
+	switch action {
+	case glfw.Release:
+		typeKey = "keyup"
+	case glfw.Press, glfw.Repeat:
+		typeKey = "keydown"
+	default:
+		fmt.Printf("go-flutter: failed to send key event, action: %v\n", action)
+		return
+	}
+
+	// Flush whatever was pending: no char callback followed it before this
+	// next event arrived, so it's sent without a character.
+	p.flush(0)
+
+	p.pending = &pendingKeyEvent{key: key, scancode: scancode, typeKey: typeKey, mods: mods}
+	if typeKey == "keyup" {
+		p.flush(0)
+	}
+}
+
+// flush sends the pending event (if any) with char, then clears it.
+func (p *keyeventPlugin) flush(char rune) {
+	if p.pending == nil {
+		return
+	}
+	ev := p.pending
+	p.pending = nil
+
+	if char == 0 {
+		char = p.lastChar
+	}
+	p.lastChar = 0
+
+	// Fold in the polled modifier state so a modifier pressed before the
+	// window regained focus is still reported, even if GLFW's per-event
+	// mods omits it.
+	p.sendFunc(p.platformEvent(ev.key, ev.scancode, ev.typeKey, ev.mods|p.polledMods, char))
+}
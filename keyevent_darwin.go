@@ -0,0 +1,104 @@
+// +build darwin
+
+package flutter
+
+import "github.com/go-gl/glfw/v3.2/glfw"
+
+// NSEvent modifier flags, matching RawKeyEventDataMacOs's expectations in
+// raw_keyboard_macos.dart.
+const (
+	macosModifierCapsLock = 1 << 16
+	macosModifierShift    = 1 << 17
+	macosModifierControl  = 1 << 18
+	macosModifierAlt      = 1 << 19
+	macosModifierMeta     = 1 << 20 // Command
+)
+
+// glfwKeyToMacosKeycode maps GLFW keys to the macOS virtual keycodes defined
+// in <Carbon/Events.h>, used by the Flutter engine's GLFW/macOS key event
+// handling to recover the right LogicalKeyboardKey.
+var glfwKeyToMacosKeycode = map[glfw.Key]int{
+	glfw.KeyA: 0x00, glfw.KeyS: 0x01, glfw.KeyD: 0x02, glfw.KeyF: 0x03,
+	glfw.KeyH: 0x04, glfw.KeyG: 0x05, glfw.KeyZ: 0x06, glfw.KeyX: 0x07,
+	glfw.KeyC: 0x08, glfw.KeyV: 0x09, glfw.KeyB: 0x0b, glfw.KeyQ: 0x0c,
+	glfw.KeyW: 0x0d, glfw.KeyE: 0x0e, glfw.KeyR: 0x0f, glfw.KeyY: 0x10,
+	glfw.KeyT: 0x11, glfw.Key1: 0x12, glfw.Key2: 0x13, glfw.Key3: 0x14,
+	glfw.Key4: 0x15, glfw.Key6: 0x16, glfw.Key5: 0x17, glfw.Key9: 0x19,
+	glfw.Key7: 0x1a, glfw.Key8: 0x1c, glfw.Key0: 0x1d, glfw.KeyO: 0x1f,
+	glfw.KeyU: 0x20, glfw.KeyI: 0x22, glfw.KeyP: 0x23, glfw.KeyL: 0x25,
+	glfw.KeyJ: 0x26, glfw.KeyK: 0x28, glfw.KeyN: 0x2d, glfw.KeyM: 0x2e,
+	glfw.KeyTab:          0x30,
+	glfw.KeySpace:        0x31,
+	glfw.KeyBackspace:    0x33,
+	glfw.KeyEscape:       0x35,
+	glfw.KeyEnter:        0x24,
+	glfw.KeyLeftSuper:    0x37,
+	glfw.KeyRightSuper:   0x36,
+	glfw.KeyLeftShift:    0x38,
+	glfw.KeyRightShift:   0x3c,
+	glfw.KeyCapsLock:     0x39,
+	glfw.KeyLeftAlt:      0x3a,
+	glfw.KeyRightAlt:     0x3d,
+	glfw.KeyLeftControl:  0x3b,
+	glfw.KeyRightControl: 0x3e,
+	glfw.KeyDelete:       0x75,
+	glfw.KeyHome:         0x73,
+	glfw.KeyEnd:          0x77,
+	glfw.KeyPageUp:       0x74,
+	glfw.KeyPageDown:     0x79,
+	glfw.KeyLeft:         0x7b,
+	glfw.KeyRight:        0x7c,
+	glfw.KeyDown:         0x7d,
+	glfw.KeyUp:           0x7e,
+}
+
+func macosKeycode(key glfw.Key) int {
+	if code, ok := glfwKeyToMacosKeycode[key]; ok {
+		return code
+	}
+	return int(key)
+}
+
+func (p *keyeventPlugin) macosModifiers(mods glfw.ModifierKey) int {
+	var m int
+	if mods&glfw.ModShift != 0 {
+		m |= macosModifierShift
+	}
+	if mods&glfw.ModControl != 0 {
+		m |= macosModifierControl
+	}
+	if mods&glfw.ModAlt != 0 {
+		m |= macosModifierAlt
+	}
+	if mods&glfw.ModSuper != 0 {
+		m |= macosModifierMeta
+	}
+	if p.capsLockOn {
+		m |= macosModifierCapsLock
+	}
+	return m
+}
+
+// platformEvent builds the flutter/keyevent payload expected by
+// RawKeyEventDataMacOs ("keymap":"macos").
+func (p *keyeventPlugin) platformEvent(key glfw.Key, scancode int, typeKey string, mods glfw.ModifierKey, char rune) interface{} {
+	var characters string
+	if char != 0 {
+		characters = string(char)
+	}
+	return struct {
+		Keymap                     string `json:"keymap"`
+		Type                       string `json:"type"`
+		KeyCode                    int    `json:"keyCode"`
+		Modifiers                  int    `json:"modifiers"`
+		Characters                 string `json:"characters"`
+		CharactersIgnoringModifiers string `json:"charactersIgnoringModifiers"`
+	}{
+		Keymap:                     "macos",
+		Type:                       typeKey,
+		KeyCode:                    macosKeycode(key),
+		Modifiers:                  p.macosModifiers(mods),
+		Characters:                 characters,
+		CharactersIgnoringModifiers: characters,
+	}
+}
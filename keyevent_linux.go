@@ -0,0 +1,105 @@
+// +build linux
+
+package flutter
+
+import "github.com/go-gl/glfw/v3.2/glfw"
+
+// Linux RawKeyEventDataLinux modifier bits, matching GDK/GTK's modifier
+// mask as consumed by the Flutter framework's raw_keyboard_linux.dart.
+const (
+	linuxModifierShift    = 1 << 0
+	linuxModifierCapsLock = 1 << 1
+	linuxModifierControl  = 1 << 2
+	linuxModifierAlt      = 1 << 3
+	linuxModifierNumLock  = 1 << 4
+	linuxModifierMeta     = 1 << 6 // Super/Mod4
+)
+
+// glfwKeyToLinuxKeysym maps the GLFW keys whose X11/XKB keysym is not a
+// direct passthrough of the GLFW key value. Printable keys already line up
+// with their Latin-1 keysym under a US-compatible layout and are handled by
+// the fallback in linuxKeysym. See the Flutter engine's GLFW embedding for
+// the reference keysym table.
+var glfwKeyToLinuxKeysym = map[glfw.Key]int{
+	glfw.KeyEnter:       0xff0d,
+	glfw.KeyKPEnter:     0xff8d,
+	glfw.KeyEscape:      0xff1b,
+	glfw.KeyTab:         0xff09,
+	glfw.KeyBackspace:   0xff08,
+	glfw.KeyDelete:      0xffff,
+	glfw.KeyHome:        0xff50,
+	glfw.KeyEnd:         0xff57,
+	glfw.KeyLeft:        0xff51,
+	glfw.KeyUp:          0xff52,
+	glfw.KeyRight:       0xff53,
+	glfw.KeyDown:        0xff54,
+	glfw.KeyPageUp:      0xff55,
+	glfw.KeyPageDown:    0xff56,
+	glfw.KeyLeftShift:    0xffe1,
+	glfw.KeyRightShift:   0xffe2,
+	glfw.KeyLeftControl:  0xffe3,
+	glfw.KeyRightControl: 0xffe4,
+	glfw.KeyLeftAlt:      0xffe9,
+	glfw.KeyRightAlt:     0xffea,
+	glfw.KeyLeftSuper:    0xffeb,
+	glfw.KeyRightSuper:   0xffec,
+	glfw.KeyCapsLock:     0xffe5,
+	glfw.KeyNumLock:      0xff7f,
+}
+
+func (p *keyeventPlugin) linuxKeysym(key glfw.Key, scancode int) int {
+	if sym, ok := glfwKeyToLinuxKeysym[key]; ok {
+		return sym
+	}
+	if p.layout != nil {
+		if r := p.layout.LogicalKey(scancode); r != 0 {
+			return int(r)
+		}
+	}
+	return int(key)
+}
+
+func (p *keyeventPlugin) linuxModifiers(mods glfw.ModifierKey) int {
+	var m int
+	if mods&glfw.ModShift != 0 {
+		m |= linuxModifierShift
+	}
+	if mods&glfw.ModControl != 0 {
+		m |= linuxModifierControl
+	}
+	if mods&glfw.ModAlt != 0 {
+		m |= linuxModifierAlt
+	}
+	if mods&glfw.ModSuper != 0 {
+		m |= linuxModifierMeta
+	}
+	if p.capsLockOn {
+		m |= linuxModifierCapsLock
+	}
+	if p.numLockOn {
+		m |= linuxModifierNumLock
+	}
+	return m
+}
+
+// platformEvent builds the flutter/keyevent payload expected by
+// RawKeyEventDataLinux ("keymap":"linux", "toolkit":"glfw").
+func (p *keyeventPlugin) platformEvent(key glfw.Key, scancode int, typeKey string, mods glfw.ModifierKey, char rune) interface{} {
+	return struct {
+		Keymap              string `json:"keymap"`
+		Toolkit             string `json:"toolkit"`
+		Type                string `json:"type"`
+		KeyCode             int    `json:"keyCode"`
+		ScanCode            int    `json:"scanCode"`
+		Modifiers           int    `json:"modifiers"`
+		UnicodeScalarValues int    `json:"unicodeScalarValues"`
+	}{
+		Keymap:              "linux",
+		Toolkit:             "glfw",
+		Type:                typeKey,
+		KeyCode:             p.linuxKeysym(key, scancode),
+		ScanCode:            scancode,
+		Modifiers:           p.linuxModifiers(mods),
+		UnicodeScalarValues: int(char),
+	}
+}
@@ -0,0 +1,152 @@
+// +build linux
+
+package flutter
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// These tests exercise keyevent.go's pending/flush logic, which is shared by
+// every platform, but assert on the concrete event struct built by
+// keyevent_linux.go's platformEvent, so they're built only on linux. The
+// pending/flush code is the same on darwin/windows, where only
+// platformEvent's struct differs; no tests are built for those platforms.
+
+// platformEventFields pulls the fields these tests care about out of the
+// interface{} platformEvent returns.
+func platformEventFields(t *testing.T, event interface{}) (typeKey string, char rune) {
+	t.Helper()
+	switch ev := event.(type) {
+	case struct {
+		Keymap              string `json:"keymap"`
+		Toolkit             string `json:"toolkit"`
+		Type                string `json:"type"`
+		KeyCode             int    `json:"keyCode"`
+		ScanCode            int    `json:"scanCode"`
+		Modifiers           int    `json:"modifiers"`
+		UnicodeScalarValues int    `json:"unicodeScalarValues"`
+	}:
+		return ev.Type, rune(ev.UnicodeScalarValues)
+	default:
+		t.Fatalf("platformEvent returned unexpected type %T", event)
+		return "", 0
+	}
+}
+
+// TestKeyeventCharOrdering exercises the exact sequence GLFW fires for a
+// printable keypress: the key callback runs first (glfwKeyCallback -> send),
+// then the char callback (glfwCharCallback -> recordChar). The typed
+// character must end up attached to that same keydown event, not get lost
+// or attached to a later, unrelated event.
+func TestKeyeventCharOrdering(t *testing.T) {
+	var sent []interface{}
+	p := &keyeventPlugin{
+		sendFunc: func(event interface{}) { sent = append(sent, event) },
+	}
+
+	p.send(glfw.KeyA, 38, glfw.Press, 0)
+	if len(sent) != 0 {
+		t.Fatalf("send() flushed before the char callback ran: %#v", sent)
+	}
+
+	p.recordChar('a')
+	if len(sent) != 1 {
+		t.Fatalf("recordChar() did not flush the pending keydown, got %d events", len(sent))
+	}
+	if typeKey, char := platformEventFields(t, sent[0]); typeKey != "keydown" || char != 'a' {
+		t.Errorf("got type=%q char=%q, want type=\"keydown\" char='a'", typeKey, char)
+	}
+
+	p.send(glfw.KeyA, 38, glfw.Release, 0)
+	if len(sent) != 2 {
+		t.Fatalf("keyup was not flushed immediately, got %d events", len(sent))
+	}
+	if typeKey, char := platformEventFields(t, sent[1]); typeKey != "keyup" || char != 0 {
+		t.Errorf("got type=%q char=%q, want type=\"keyup\" char=0", typeKey, char)
+	}
+}
+
+// TestKeyeventPolledModsClearOnRealRelease covers alt-tabbing back into the
+// window while holding a modifier (which seeds polledMods from
+// glfwFocusCallback's poll) followed by actually releasing that modifier:
+// the released bit must stop being OR'd into later events, since GLFW's own
+// live mods for them already correctly omits it.
+func TestKeyeventPolledModsClearOnRealRelease(t *testing.T) {
+	var sent []interface{}
+	p := &keyeventPlugin{
+		sendFunc:            func(event interface{}) { sent = append(sent, event) },
+		pressedModifierKeys: map[glfw.Key]bool{},
+		polledMods:          glfw.ModControl,
+	}
+
+	p.send(glfw.KeyLeftControl, 37, glfw.Release, 0)
+	p.send(glfw.KeyA, 38, glfw.Press, 0)
+	p.recordChar('a')
+
+	if len(sent) != 2 {
+		t.Fatalf("got %d events, want 2", len(sent))
+	}
+	ev := sent[1].(struct {
+		Keymap              string `json:"keymap"`
+		Toolkit             string `json:"toolkit"`
+		Type                string `json:"type"`
+		KeyCode             int    `json:"keyCode"`
+		ScanCode            int    `json:"scanCode"`
+		Modifiers           int    `json:"modifiers"`
+		UnicodeScalarValues int    `json:"unicodeScalarValues"`
+	})
+	if ev.Modifiers&linuxModifierControl != 0 {
+		t.Errorf("event after the real Control release still reports Control held: modifiers=%#x", ev.Modifiers)
+	}
+}
+
+// TestKeyeventSendUpdatesPressedModifierKeys covers a modifier pressed while
+// the window already has focus (the common path, as opposed to
+// glfwFocusCallback's own resync poll): send must record it in
+// pressedModifierKeys too, so a later focus-regain diffs against
+// up-to-date state instead of synthesizing a duplicate event for a
+// press/release that was already reported here.
+func TestKeyeventSendUpdatesPressedModifierKeys(t *testing.T) {
+	p := &keyeventPlugin{
+		sendFunc:            func(event interface{}) {},
+		pressedModifierKeys: map[glfw.Key]bool{},
+	}
+
+	p.send(glfw.KeyLeftShift, 50, glfw.Press, glfw.ModShift)
+	if !p.pressedModifierKeys[glfw.KeyLeftShift] {
+		t.Errorf("pressedModifierKeys[KeyLeftShift] = false after a real press, want true")
+	}
+
+	p.send(glfw.KeyLeftShift, 50, glfw.Release, 0)
+	if p.pressedModifierKeys[glfw.KeyLeftShift] {
+		t.Errorf("pressedModifierKeys[KeyLeftShift] = true after a real release, want false")
+	}
+}
+
+// TestKeyeventNonPrintableKeyFlushesOnNextEvent covers a non-printable key
+// (no char callback ever follows it): it must still be flushed, once the
+// next key event arrives, rather than being dropped.
+func TestKeyeventNonPrintableKeyFlushesOnNextEvent(t *testing.T) {
+	var sent []interface{}
+	p := &keyeventPlugin{
+		sendFunc: func(event interface{}) { sent = append(sent, event) },
+	}
+
+	p.send(glfw.KeyEscape, 9, glfw.Press, 0)
+	if len(sent) != 0 {
+		t.Fatalf("send() flushed before the next event arrived: %#v", sent)
+	}
+
+	p.send(glfw.KeyEscape, 9, glfw.Release, 0)
+	if len(sent) != 2 {
+		t.Fatalf("got %d events, want the queued keydown flushed ahead of the keyup", len(sent))
+	}
+	if typeKey, char := platformEventFields(t, sent[0]); typeKey != "keydown" || char != 0 {
+		t.Errorf("got type=%q char=%q, want type=\"keydown\" char=0", typeKey, char)
+	}
+	if typeKey, _ := platformEventFields(t, sent[1]); typeKey != "keyup" {
+		t.Errorf("got type=%q, want type=\"keyup\"", typeKey)
+	}
+}
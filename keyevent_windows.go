@@ -0,0 +1,95 @@
+// +build windows
+
+package flutter
+
+import "github.com/go-gl/glfw/v3.2/glfw"
+
+// Win32 modifier bits, matching RawKeyEventDataWindows's expectations in
+// raw_keyboard_windows.dart.
+const (
+	windowsModifierShift    = 1 << 0
+	windowsModifierControl  = 1 << 1
+	windowsModifierAlt      = 1 << 2
+	windowsModifierMeta     = 1 << 3 // Windows key
+	windowsModifierCapsLock = 1 << 4
+	windowsModifierNumLock  = 1 << 5
+)
+
+// glfwKeyToWindowsVK maps GLFW keys to Win32 virtual-key codes (winuser.h)
+// for the keys whose VK differs from the ASCII passthrough GLFW already
+// uses for letters and digits.
+var glfwKeyToWindowsVK = map[glfw.Key]int{
+	glfw.KeyEnter:         0x0d, // VK_RETURN
+	glfw.KeyEscape:        0x1b, // VK_ESCAPE
+	glfw.KeyTab:           0x09, // VK_TAB
+	glfw.KeyBackspace:     0x08, // VK_BACK
+	glfw.KeyDelete:        0x2e, // VK_DELETE
+	glfw.KeyHome:          0x24, // VK_HOME
+	glfw.KeyEnd:           0x23, // VK_END
+	glfw.KeyLeft:          0x25, // VK_LEFT
+	glfw.KeyUp:            0x26, // VK_UP
+	glfw.KeyRight:         0x27, // VK_RIGHT
+	glfw.KeyDown:          0x28, // VK_DOWN
+	glfw.KeyPageUp:        0x21, // VK_PRIOR
+	glfw.KeyPageDown:      0x22, // VK_NEXT
+	glfw.KeyLeftShift:     0xa0, // VK_LSHIFT
+	glfw.KeyRightShift:    0xa1, // VK_RSHIFT
+	glfw.KeyLeftControl:   0xa2, // VK_LCONTROL
+	glfw.KeyRightControl:  0xa3, // VK_RCONTROL
+	glfw.KeyLeftAlt:       0xa4, // VK_LMENU
+	glfw.KeyRightAlt:      0xa5, // VK_RMENU
+	glfw.KeyLeftSuper:     0x5b, // VK_LWIN
+	glfw.KeyRightSuper:    0x5c, // VK_RWIN
+	glfw.KeyCapsLock:      0x14, // VK_CAPITAL
+	glfw.KeyNumLock:       0x90, // VK_NUMLOCK
+}
+
+func windowsVK(key glfw.Key) int {
+	if vk, ok := glfwKeyToWindowsVK[key]; ok {
+		return vk
+	}
+	return int(key)
+}
+
+func (p *keyeventPlugin) windowsModifiers(mods glfw.ModifierKey) int {
+	var m int
+	if mods&glfw.ModShift != 0 {
+		m |= windowsModifierShift
+	}
+	if mods&glfw.ModControl != 0 {
+		m |= windowsModifierControl
+	}
+	if mods&glfw.ModAlt != 0 {
+		m |= windowsModifierAlt
+	}
+	if mods&glfw.ModSuper != 0 {
+		m |= windowsModifierMeta
+	}
+	if p.capsLockOn {
+		m |= windowsModifierCapsLock
+	}
+	if p.numLockOn {
+		m |= windowsModifierNumLock
+	}
+	return m
+}
+
+// platformEvent builds the flutter/keyevent payload expected by
+// RawKeyEventDataWindows ("keymap":"windows").
+func (p *keyeventPlugin) platformEvent(key glfw.Key, scancode int, typeKey string, mods glfw.ModifierKey, char rune) interface{} {
+	return struct {
+		Keymap            string `json:"keymap"`
+		Type              string `json:"type"`
+		KeyCode           int    `json:"keyCode"`
+		ScanCode          int    `json:"scanCode"`
+		Modifiers         int    `json:"modifiers"`
+		CharacterCodePoint int   `json:"characterCodePoint"`
+	}{
+		Keymap:             "windows",
+		Type:               typeKey,
+		KeyCode:            windowsVK(key),
+		ScanCode:           scancode,
+		Modifiers:          p.windowsModifiers(mods),
+		CharacterCodePoint: int(char),
+	}
+}
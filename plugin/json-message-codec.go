@@ -16,5 +16,9 @@ func (j JSONMessageCodec) EncodeMessage(message interface{}) (binaryMessage []by
 
 // DecodeMessage decodes a slice of bytes to a json message.
 func (j JSONMessageCodec) DecodeMessage(binaryMessage []byte) (message interface{}, err error) {
-	return json.Marshal([]interface{}{binaryMessage})
+	if binaryMessage == nil {
+		return nil, nil
+	}
+	err = json.Unmarshal(binaryMessage, &message)
+	return message, err
 }
@@ -0,0 +1,43 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONMessageCodecRoundTrip(t *testing.T) {
+	codec := JSONMessageCodec{}
+
+	want := map[string]interface{}{
+		"text":  "hello",
+		"count": float64(3),
+		"nested": map[string]interface{}{
+			"ok": true,
+		},
+	}
+
+	encoded, err := codec.EncodeMessage(want)
+	if err != nil {
+		t.Fatalf("EncodeMessage failed: %v", err)
+	}
+
+	got, err := codec.DecodeMessage(encoded)
+	if err != nil {
+		t.Fatalf("DecodeMessage failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("round-trip mismatch: want %#v, got %#v", want, got)
+	}
+}
+
+func TestJSONMessageCodecDecodeNil(t *testing.T) {
+	codec := JSONMessageCodec{}
+	got, err := codec.DecodeMessage(nil)
+	if err != nil {
+		t.Fatalf("DecodeMessage(nil) failed: %v", err)
+	}
+	if got != nil {
+		t.Errorf("DecodeMessage(nil) = %#v, want nil", got)
+	}
+}
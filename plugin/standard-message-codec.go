@@ -0,0 +1,366 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// Type bytes used by Flutter's standard binary message encoding, see
+// https://api.flutter.dev/flutter/services/StandardMessageCodec-class.html
+const (
+	standardTypeNull        byte = 0
+	standardTypeTrue        byte = 1
+	standardTypeFalse       byte = 2
+	standardTypeInt32       byte = 3
+	standardTypeInt64       byte = 4
+	standardTypeFloat64     byte = 6
+	standardTypeString      byte = 7
+	standardTypeUint8List   byte = 8
+	standardTypeInt32List   byte = 9
+	standardTypeInt64List   byte = 10
+	standardTypeFloat64List byte = 11
+	standardTypeList        byte = 12
+	standardTypeMap         byte = 13
+)
+
+// StandardMessageCodec implements a MessageCodec using Flutter's standard
+// binary message encoding, the default codec used by BasicMessageChannel and
+// by most first-party plugins (e.g. path_provider, shared_preferences).
+type StandardMessageCodec struct{}
+
+var _ MessageCodec = StandardMessageCodec{}
+
+// EncodeMessage encodes message to Flutter's standard binary representation.
+func (s StandardMessageCodec) EncodeMessage(message interface{}) (binaryMessage []byte, err error) {
+	buf := &bytes.Buffer{}
+	if err := writeStandardValue(buf, message); err != nil {
+		return nil, errors.Wrap(err, "failed to encode standard message")
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeMessage decodes a Flutter standard binary message.
+func (s StandardMessageCodec) DecodeMessage(binaryMessage []byte) (message interface{}, err error) {
+	if binaryMessage == nil {
+		return nil, nil
+	}
+	r := &standardReader{data: binaryMessage}
+	value, err := r.readValue()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode standard message")
+	}
+	return value, nil
+}
+
+// writeStandardSize writes size using the codec's variable-length encoding:
+// a single byte for sizes under 254, otherwise a marker byte followed by a
+// 16- or 32-bit little-endian length.
+func writeStandardSize(buf *bytes.Buffer, size int) {
+	switch {
+	case size < 254:
+		buf.WriteByte(byte(size))
+	case size <= 0xffff:
+		buf.WriteByte(254)
+		binary.Write(buf, binary.LittleEndian, uint16(size))
+	default:
+		buf.WriteByte(255)
+		binary.Write(buf, binary.LittleEndian, uint32(size))
+	}
+}
+
+// writeStandardAlignment pads buf with zero bytes so its length becomes a
+// multiple of alignment, matching the padding the Dart codec inserts before
+// fixed-width numeric lists and doubles.
+func writeStandardAlignment(buf *bytes.Buffer, alignment int) {
+	if mod := buf.Len() % alignment; mod != 0 {
+		buf.Write(make([]byte, alignment-mod))
+	}
+}
+
+func writeStandardValue(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(standardTypeNull)
+	case bool:
+		if v {
+			buf.WriteByte(standardTypeTrue)
+		} else {
+			buf.WriteByte(standardTypeFalse)
+		}
+	case int:
+		return writeStandardValue(buf, int64(v))
+	case int32:
+		buf.WriteByte(standardTypeInt32)
+		writeStandardAlignment(buf, 4)
+		binary.Write(buf, binary.LittleEndian, v)
+	case int64:
+		if v >= math.MinInt32 && v <= math.MaxInt32 {
+			return writeStandardValue(buf, int32(v))
+		}
+		buf.WriteByte(standardTypeInt64)
+		binary.Write(buf, binary.LittleEndian, v)
+	case float32:
+		return writeStandardValue(buf, float64(v))
+	case float64:
+		buf.WriteByte(standardTypeFloat64)
+		writeStandardAlignment(buf, 8)
+		binary.Write(buf, binary.LittleEndian, v)
+	case string:
+		buf.WriteByte(standardTypeString)
+		b := []byte(v)
+		writeStandardSize(buf, len(b))
+		buf.Write(b)
+	case []byte:
+		buf.WriteByte(standardTypeUint8List)
+		writeStandardSize(buf, len(v))
+		buf.Write(v)
+	case []int32:
+		buf.WriteByte(standardTypeInt32List)
+		writeStandardSize(buf, len(v))
+		writeStandardAlignment(buf, 4)
+		for _, n := range v {
+			binary.Write(buf, binary.LittleEndian, n)
+		}
+	case []int64:
+		buf.WriteByte(standardTypeInt64List)
+		writeStandardSize(buf, len(v))
+		writeStandardAlignment(buf, 8)
+		for _, n := range v {
+			binary.Write(buf, binary.LittleEndian, n)
+		}
+	case []float64:
+		buf.WriteByte(standardTypeFloat64List)
+		writeStandardSize(buf, len(v))
+		writeStandardAlignment(buf, 8)
+		for _, n := range v {
+			binary.Write(buf, binary.LittleEndian, n)
+		}
+	case []interface{}:
+		buf.WriteByte(standardTypeList)
+		writeStandardSize(buf, len(v))
+		for _, item := range v {
+			if err := writeStandardValue(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		buf.WriteByte(standardTypeMap)
+		writeStandardSize(buf, len(v))
+		for k, item := range v {
+			if err := writeStandardValue(buf, k); err != nil {
+				return err
+			}
+			if err := writeStandardValue(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[interface{}]interface{}:
+		buf.WriteByte(standardTypeMap)
+		writeStandardSize(buf, len(v))
+		for k, item := range v {
+			if err := writeStandardValue(buf, k); err != nil {
+				return err
+			}
+			if err := writeStandardValue(buf, item); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.Errorf("standard codec: unsupported type %T", value)
+	}
+	return nil
+}
+
+// standardReader walks a standard-codec byte slice, tracking its absolute
+// position so it can reproduce the encoder's alignment padding.
+type standardReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *standardReader) readByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, errors.New("standard codec: unexpected end of message")
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *standardReader) readBytes(n int) ([]byte, error) {
+	if r.pos+n > len(r.data) {
+		return nil, errors.New("standard codec: unexpected end of message")
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *standardReader) alignTo(alignment int) {
+	if mod := r.pos % alignment; mod != 0 {
+		r.pos += alignment - mod
+	}
+}
+
+func (r *standardReader) readSize() (int, error) {
+	b, err := r.readByte()
+	if err != nil {
+		return 0, err
+	}
+	switch b {
+	case 254:
+		raw, err := r.readBytes(2)
+		if err != nil {
+			return 0, err
+		}
+		return int(binary.LittleEndian.Uint16(raw)), nil
+	case 255:
+		raw, err := r.readBytes(4)
+		if err != nil {
+			return 0, err
+		}
+		return int(binary.LittleEndian.Uint32(raw)), nil
+	default:
+		return int(b), nil
+	}
+}
+
+func (r *standardReader) readValue() (interface{}, error) {
+	typeByte, err := r.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch typeByte {
+	case standardTypeNull:
+		return nil, nil
+	case standardTypeTrue:
+		return true, nil
+	case standardTypeFalse:
+		return false, nil
+	case standardTypeInt32:
+		r.alignTo(4)
+		raw, err := r.readBytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return int32(binary.LittleEndian.Uint32(raw)), nil
+	case standardTypeInt64:
+		raw, err := r.readBytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return int64(binary.LittleEndian.Uint64(raw)), nil
+	case standardTypeFloat64:
+		r.alignTo(8)
+		raw, err := r.readBytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(raw)), nil
+	case standardTypeString:
+		size, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := r.readBytes(size)
+		if err != nil {
+			return nil, err
+		}
+		return string(raw), nil
+	case standardTypeUint8List:
+		size, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := r.readBytes(size)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, size)
+		copy(out, raw)
+		return out, nil
+	case standardTypeInt32List:
+		size, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		r.alignTo(4)
+		out := make([]int32, size)
+		for i := range out {
+			raw, err := r.readBytes(4)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = int32(binary.LittleEndian.Uint32(raw))
+		}
+		return out, nil
+	case standardTypeInt64List:
+		size, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		r.alignTo(8)
+		out := make([]int64, size)
+		for i := range out {
+			raw, err := r.readBytes(8)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = int64(binary.LittleEndian.Uint64(raw))
+		}
+		return out, nil
+	case standardTypeFloat64List:
+		size, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		r.alignTo(8)
+		out := make([]float64, size)
+		for i := range out {
+			raw, err := r.readBytes(8)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = math.Float64frombits(binary.LittleEndian.Uint64(raw))
+		}
+		return out, nil
+	case standardTypeList:
+		size, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		out := make([]interface{}, size)
+		for i := range out {
+			v, err := r.readValue()
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	case standardTypeMap:
+		size, err := r.readSize()
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[interface{}]interface{}, size)
+		for i := 0; i < size; i++ {
+			k, err := r.readValue()
+			if err != nil {
+				return nil, err
+			}
+			v, err := r.readValue()
+			if err != nil {
+				return nil, err
+			}
+			out[k] = v
+		}
+		return out, nil
+	default:
+		return nil, errors.Errorf("standard codec: unknown type byte 0x%x", typeByte)
+	}
+}
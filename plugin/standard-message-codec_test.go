@@ -0,0 +1,88 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStandardMessageCodecRoundTrip(t *testing.T) {
+	codec := StandardMessageCodec{}
+
+	// want is the value DecodeMessage is expected to produce for input. It
+	// defaults to input itself, except where EncodeMessage downgrades an
+	// int64 that fits in 32 bits to the standard codec's int32 wire type
+	// (standard-message-codec.go's writeStandardValue), in which case
+	// DecodeMessage hands back an int32, not the original int64.
+	tests := []struct {
+		name  string
+		input interface{}
+		want  interface{}
+	}{
+		{name: "nil", input: nil},
+		{name: "true", input: true},
+		{name: "false", input: false},
+		{name: "int64 downgraded to int32", input: int64(42), want: int32(42)},
+		{name: "int64 too large for int32", input: int64(1) << 40},
+		{name: "float64", input: 3.14159},
+		{name: "string", input: "hello, 世界"},
+		{name: "byte list", input: []byte{0x00, 0x01, 0xff}},
+		{name: "int32 list", input: []int32{1, -2, 3}},
+		{name: "int64 list", input: []int64{1 << 40, -(1 << 40)}},
+		{name: "float64 list", input: []float64{1.5, -2.25, 0}},
+		{
+			name:  "list with downgraded int",
+			input: []interface{}{"a", int64(1), true, nil},
+			want:  []interface{}{"a", int32(1), true, nil},
+		},
+		{
+			name: "nested map with downgraded int",
+			input: map[interface{}]interface{}{
+				"name": "go-flutter",
+				"nested": map[interface{}]interface{}{
+					"count": int64(3),
+					"tags":  []interface{}{"x", "y"},
+				},
+			},
+			want: map[interface{}]interface{}{
+				"name": "go-flutter",
+				"nested": map[interface{}]interface{}{
+					"count": int32(3),
+					"tags":  []interface{}{"x", "y"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := codec.EncodeMessage(tt.input)
+			if err != nil {
+				t.Fatalf("EncodeMessage(%#v) failed: %v", tt.input, err)
+			}
+
+			got, err := codec.DecodeMessage(encoded)
+			if err != nil {
+				t.Fatalf("DecodeMessage after encoding %#v failed: %v", tt.input, err)
+			}
+
+			want := tt.want
+			if want == nil && tt.input != nil {
+				want = tt.input
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("round-trip mismatch: want %#v, got %#v", want, got)
+			}
+		})
+	}
+}
+
+func TestStandardMessageCodecDecodeNil(t *testing.T) {
+	codec := StandardMessageCodec{}
+	got, err := codec.DecodeMessage(nil)
+	if err != nil {
+		t.Fatalf("DecodeMessage(nil) failed: %v", err)
+	}
+	if got != nil {
+		t.Errorf("DecodeMessage(nil) = %#v, want nil", got)
+	}
+}
@@ -10,12 +10,6 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Android KeyEvent constants from https://developer.android.com/reference/android/view/KeyEvent
-const androidMetaStateShift = 1 << 0
-const androidMetaStateAlt = 1 << 1
-const androidMetaStateCtrl = 1 << 12
-const androidMetaStateMeta = 1 << 16
-
 const textinputChannelName = "flutter/textinput"
 const keyEventChannelName = "flutter/keyevent"
 
@@ -26,7 +20,14 @@ type textinputPlugin struct {
 	window      *glfw.Window
 	textChannel *plugin.MethodChannel
 
-	keyEventChannel *plugin.BasicMessageChannel
+	// invokeMethod is how sendUpdateEditingState/performAction call back
+	// into the framework; a field (rather than calling
+	// p.textChannel.InvokeMethod directly) so textinput_test.go can verify
+	// the payloads sent without a real MethodChannel. InitPluginGLFW points
+	// it at p.textChannel.InvokeMethod.
+	invokeMethod func(method string, arguments interface{}) (reply interface{}, err error)
+
+	keyevent *keyeventPlugin
 
 	keyboardLayout KeyboardShortcuts
 
@@ -35,9 +36,35 @@ type textinputPlugin struct {
 	wordTravellerKeyShift glfw.ModifierKey
 
 	clientID        float64
+	inputType       string
+	inputAction     string
 	word            []rune
 	selectionBase   int
 	selectionExtent int
+
+	// composingBase/composingExtent bound the IME preedit region within
+	// word. Both are -1 when nothing is being composed, matching the
+	// framework's convention for TextRange.empty.
+	composingBase   int
+	composingExtent int
+
+	// onShow/onHide let the embedder react to TextInput.show/hide, e.g. to
+	// toggle an on-screen keyboard. Both are optional.
+	onShow func()
+	onHide func()
+}
+
+// argsEditingState mirrors the framework's TextEditingValue as sent on
+// TextInput.setEditingState and expected back on
+// TextInputClient.updateEditingState.
+type argsEditingState struct {
+	Text                   string `json:"text"`
+	SelectionBase          int    `json:"selectionBase"`
+	SelectionExtent        int    `json:"selectionExtent"`
+	SelectionAffinity      string `json:"selectionAffinity"`
+	SelectionIsDirectional bool   `json:"selectionIsDirectional"`
+	ComposingBase          int    `json:"composingBase"`
+	ComposingExtent        int    `json:"composingExtent"`
 }
 
 // all hardcoded because theres not pluggable renderer system.
@@ -48,6 +75,8 @@ var _ PluginGLFW = &textinputPlugin{} // compile-time type check
 
 func (p *textinputPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
 	p.messenger = messenger
+	p.composingBase = -1
+	p.composingExtent = -1
 
 	// set modifier keys based on OS
 	switch runtime.GOOS {
@@ -67,10 +96,29 @@ func (p *textinputPlugin) InitPlugin(messenger plugin.BinaryMessenger) error {
 func (p *textinputPlugin) InitPluginGLFW(window *glfw.Window) error {
 	p.window = window
 	p.textChannel = plugin.NewMethodChannel(p.messenger, textinputChannelName, plugin.JSONMethodCodec{})
-	p.keyEventChannel = plugin.NewBasicMessageChannel(p.messenger, keyEventChannelName, plugin.JSONMessageCodec{})
+	p.invokeMethod = p.textChannel.InvokeMethod
 	p.textChannel.HandleFuncSync("TextInput.setClient", p.handleSetClient)
 	p.textChannel.HandleFuncSync("TextInput.clearClient", p.handleClearClient)
 	p.textChannel.HandleFuncSync("TextInput.setEditingState", p.handleSetEditingState)
+	p.textChannel.HandleFuncSync("TextInput.show", p.handleShow)
+	p.textChannel.HandleFuncSync("TextInput.hide", p.handleHide)
+	p.textChannel.HandleFuncSync("TextInput.setEditableSizeAndTransform", p.handleSetEditableSizeAndTransform)
+	p.textChannel.HandleFuncSync("TextInput.setStyle", p.handleSetStyle)
+
+	p.keyevent = &keyeventPlugin{}
+	if err := p.keyevent.InitPlugin(p.messenger); err != nil {
+		return errors.Wrap(err, "failed to initialize keyevent plugin")
+	}
+	if err := p.keyevent.InitPluginGLFW(window); err != nil {
+		return errors.Wrap(err, "failed to initialize keyevent plugin")
+	}
+
+	window.SetPreeditCallback(p.glfwPreeditCallback)
+
+	p.keyboardLayout = NewKeyboardShortcuts(p.keyevent.layout)
+	p.keyevent.onLayoutChanged = func() {
+		p.keyboardLayout = NewKeyboardShortcuts(p.keyevent.layout)
+	}
 
 	return nil
 }
@@ -82,6 +130,20 @@ func (p *textinputPlugin) handleSetClient(arguments interface{}) (reply interfac
 		return nil, errors.Wrap(err, "failed to decode json arguments for handleSetClient")
 	}
 	p.clientID = args[0].(float64)
+
+	p.inputAction = "TextInputAction.done"
+	if len(args) > 1 {
+		if config, ok := args[1].(map[string]interface{}); ok {
+			if inputAction, ok := config["inputAction"].(string); ok {
+				p.inputAction = inputAction
+			}
+			if keyboardType, ok := config["keyboardType"].(map[string]interface{}); ok {
+				if name, ok := keyboardType["name"].(string); ok {
+					p.inputType = name
+				}
+			}
+		}
+	}
 	return nil, nil
 }
 
@@ -90,6 +152,34 @@ func (p *textinputPlugin) handleClearClient(arguments interface{}) (reply interf
 	return nil, nil
 }
 
+func (p *textinputPlugin) handleShow(arguments interface{}) (reply interface{}, err error) {
+	if p.onShow != nil {
+		p.onShow()
+	}
+	return nil, nil
+}
+
+func (p *textinputPlugin) handleHide(arguments interface{}) (reply interface{}, err error) {
+	if p.onHide != nil {
+		p.onHide()
+	}
+	return nil, nil
+}
+
+// handleSetEditableSizeAndTransform receives the on-screen geometry of the
+// focused text field. go-flutter has no on-screen keyboard or IME popup to
+// position, so this is a no-op kept only to satisfy the framework's method
+// channel contract.
+func (p *textinputPlugin) handleSetEditableSizeAndTransform(arguments interface{}) (reply interface{}, err error) {
+	return nil, nil
+}
+
+// handleSetStyle receives the text style of the focused field, used by
+// mobile embedders to theme the on-screen keyboard. Not applicable here.
+func (p *textinputPlugin) handleSetStyle(arguments interface{}) (reply interface{}, err error) {
+	return nil, nil
+}
+
 func (p *textinputPlugin) handleSetEditingState(arguments interface{}) (reply interface{}, err error) {
 	if p.clientID == 0 {
 		return nil, errors.New("cannot set editing state when no client is selected")
@@ -104,14 +194,100 @@ func (p *textinputPlugin) handleSetEditingState(arguments interface{}) (reply in
 	p.word = []rune(editingState.Text)
 	p.selectionBase = editingState.SelectionBase
 	p.selectionExtent = editingState.SelectionExtent
+	p.composingBase = editingState.ComposingBase
+	p.composingExtent = editingState.ComposingExtent
 	return nil, nil
 }
 
 func (p *textinputPlugin) glfwCharCallback(w *glfw.Window, char rune) {
+	p.keyevent.recordChar(char)
+
 	if p.clientID == 0 {
 		return
 	}
 	p.addChar([]rune{char})
+	// A committed character always ends whatever was being composed.
+	// clearComposing reports the editing state only if a composing range was
+	// active; the state is then reported again unconditionally below.
+	p.clearComposing()
+	p.sendUpdateEditingState()
+}
+
+// glfwPreeditCallback renders the IME's in-progress preedit text by
+// replacing the current composing range with it, then reports the new
+// composing bounds so the framework can underline it like it does on
+// mobile. An empty preedit string means the IME committed or cancelled the
+// composition, so the composing range is cleared instead.
+func (p *textinputPlugin) glfwPreeditCallback(w *glfw.Window, preeditString string, blockCount int, blockSizes []int, focusedBlock int, caret int) {
+	if p.clientID == 0 {
+		return
+	}
+
+	preedit := []rune(preeditString)
+	if len(preedit) == 0 {
+		p.clearComposing()
+		return
+	}
+
+	start := p.selectionBase
+	end := p.selectionExtent
+	if p.composingBase >= 0 {
+		start = p.composingBase
+		end = p.composingExtent
+	}
+	// selectionBase/selectionExtent (and the composing range derived from
+	// them) aren't ordered: TextSelection.baseOffset can be greater than
+	// extentOffset for a backward drag-selection.
+	if start > end {
+		start, end = end, start
+	}
+
+	p.word = append(p.word[:start:start], append(preedit, p.word[end:]...)...)
+	p.composingBase = start
+	p.composingExtent = start + len(preedit)
+	p.selectionBase = start + caret
+	p.selectionExtent = p.selectionBase
+
+	p.sendUpdateEditingState()
+}
+
+// clearComposing commits whatever text is currently in the composing range
+// (it is already part of p.word) and clears the composing markers.
+func (p *textinputPlugin) clearComposing() {
+	if p.composingBase < 0 {
+		return
+	}
+	p.composingBase = -1
+	p.composingExtent = -1
+	p.sendUpdateEditingState()
+}
+
+// sendUpdateEditingState reports the current TextEditingValue back to the
+// framework so the on-screen TextField stays in sync with local mutations
+// (typing, cursor movement, cut/paste, ...). See the engine's GLFW
+// text_input_plugin.cc for the method/argument shape this mirrors.
+func (p *textinputPlugin) sendUpdateEditingState() {
+	p.invokeMethod("TextInputClient.updateEditingState", []interface{}{
+		p.clientID,
+		argsEditingState{
+			Text:                   string(p.word),
+			SelectionBase:          p.selectionBase,
+			SelectionExtent:        p.selectionExtent,
+			SelectionAffinity:      "TextAffinity.downstream",
+			SelectionIsDirectional: false,
+			ComposingBase:          p.composingBase,
+			ComposingExtent:        p.composingExtent,
+		},
+	})
+}
+
+// performAction notifies the framework that the given TextInputAction (e.g.
+// "TextInputAction.done") was triggered for the focused text field.
+func (p *textinputPlugin) performAction(action string) {
+	p.invokeMethod("TextInputClient.performAction", []interface{}{
+		p.clientID,
+		action,
+	})
 }
 
 func (p *textinputPlugin) glfwKeyCallback(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
@@ -144,36 +320,46 @@ func (p *textinputPlugin) glfwKeyCallback(window *glfw.Window, key glfw.Key, sca
 			return
 		}
 
+		stateChanged := false
+
 		switch key {
 		case glfw.KeyEnter:
-			if mods == p.modifierKey {
-				p.performAction("done")
+			if mods == p.modifierKey || p.inputType != "TextInputType.multiline" {
+				p.performAction(p.inputAction)
 			} else {
 				p.addChar([]rune{'\n'})
-				p.performAction("newline")
+				p.performAction("TextInputAction.newline")
 			}
+			stateChanged = true
 
 		case glfw.KeyHome:
 			p.MoveCursorHome(modsIsModfifier, modsIsShift, modsIsWordModifierShift, modsIsWordModifier)
+			stateChanged = true
 
 		case glfw.KeyEnd:
 			p.MoveCursorEnd(modsIsModfifier, modsIsShift, modsIsWordModifierShift, modsIsWordModifier)
+			stateChanged = true
 
 		case glfw.KeyLeft:
 			p.MoveCursorLeft(modsIsModfifier, modsIsShift, modsIsWordModifierShift, modsIsWordModifier)
+			stateChanged = true
 
 		case glfw.KeyRight:
 			p.MoveCursorRight(modsIsModfifier, modsIsShift, modsIsWordModifierShift, modsIsWordModifier)
+			stateChanged = true
 
 		case glfw.KeyDelete:
 			p.Delete(modsIsModfifier, modsIsShift, modsIsWordModifierShift, modsIsWordModifier)
+			stateChanged = true
 
 		case glfw.KeyBackspace:
 			p.Backspace(modsIsModfifier, modsIsShift, modsIsWordModifierShift, modsIsWordModifier)
+			stateChanged = true
 
 		case p.keyboardLayout.SelectAll:
 			if mods == p.modifierKey {
 				p.SelectAll()
+				stateChanged = true
 			}
 
 		case p.keyboardLayout.Copy:
@@ -187,6 +373,7 @@ func (p *textinputPlugin) glfwKeyCallback(window *glfw.Window, key glfw.Key, sca
 				_, _, selectedContent := p.GetSelectedText()
 				window.SetClipboardString(selectedContent)
 				p.RemoveSelectedText()
+				stateChanged = true
 			}
 
 		case p.keyboardLayout.Paste:
@@ -197,49 +384,15 @@ func (p *textinputPlugin) glfwKeyCallback(window *glfw.Window, key glfw.Key, sca
 					return
 				}
 				p.addChar([]rune(clpString))
+				stateChanged = true
 			}
 		}
-	}
-
-	// key events
 
-	// TODO: Stop using the android keymap and translate the glfw keycode to the
-	// platfom native one
-	// BUG: the LogicalKeyboardKey isn't the right one
-	// https://github.com/flutter/flutter/blob/1f2972c7b6a8503f7c6a5dfa180521a6f7efd472/packages/flutter/lib/src/services/raw_keyboard_android.dart#L116
-
-	// MacOS example: flutter/engine/pull/8219
-	// Linux/Windows Watch: google/flutter-desktop-embedding/issues/323
-	var typeKey string
-	if action == glfw.Release {
-		typeKey = "keyup"
-	} else if action == glfw.Press {
-		typeKey = "keydown"
-	} else {
-		fmt.Printf("go-flutter: failed to send key event, action: %v\n", action)
-		return
-	}
-
-	event := struct {
-		KeyCode   int    `json:"keyCode"`
-		Keymap    string `json:"keymap"`
-		Type      string `json:"type"`
-		MetaState int    `json:"metaState"`
-	}{
-		int(key), "android", typeKey,
-		conditionalInt(mods&glfw.ModShift != 0, androidMetaStateShift) |
-			conditionalInt(mods&glfw.ModAlt != 0, androidMetaStateAlt) |
-			conditionalInt(mods&glfw.ModControl != 0, androidMetaStateCtrl) |
-			conditionalInt(mods&glfw.ModSuper != 0, androidMetaStateMeta),
+		if stateChanged {
+			p.sendUpdateEditingState()
+		}
 	}
-	p.keyEventChannel.Send(event)
-
-}
 
-// Int returns val1 if condition, otherwise 0
-func conditionalInt(condition bool, val1 int) int {
-	if condition {
-		return val1
-	}
-	return 0
+	// key events, translated to the platform-native keymap by keyeventPlugin.
+	p.keyevent.send(key, scancode, action, mods)
 }
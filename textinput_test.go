@@ -0,0 +1,149 @@
+package flutter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+type invocation struct {
+	method string
+	args   interface{}
+}
+
+func newTestTextinputPlugin(invoked *[]invocation) *textinputPlugin {
+	return &textinputPlugin{
+		clientID:        1,
+		inputAction:     "TextInputAction.done",
+		inputType:       "TextInputType.text",
+		modifierKey:     glfw.ModControl,
+		composingBase:   -1,
+		composingExtent: -1,
+		keyevent: &keyeventPlugin{
+			sendFunc:            func(event interface{}) {},
+			pressedModifierKeys: map[glfw.Key]bool{},
+		},
+		invokeMethod: func(method string, args interface{}) (interface{}, error) {
+			*invoked = append(*invoked, invocation{method, args})
+			return nil, nil
+		},
+	}
+}
+
+// TestTextinputKeyEnterPerformsInputAction covers glfwKeyCallback's handling
+// of Enter for a non-multiline field (the common case: a single-line
+// TextField submitted with the keyboard's Done/Go/Search/... action): it
+// must invoke TextInputClient.performAction with the client's configured
+// inputAction, not insert a newline.
+func TestTextinputKeyEnterPerformsInputAction(t *testing.T) {
+	var invoked []invocation
+	p := newTestTextinputPlugin(&invoked)
+
+	p.glfwKeyCallback(nil, glfw.KeyEnter, 36, glfw.Press, 0)
+
+	if len(invoked) == 0 || invoked[0].method != "TextInputClient.performAction" {
+		t.Fatalf("got invocations %#v, want TextInputClient.performAction first", invoked)
+	}
+	args, ok := invoked[0].args.([]interface{})
+	if !ok || len(args) != 2 {
+		t.Fatalf("unexpected performAction args: %#v", invoked[0].args)
+	}
+	if args[0] != p.clientID {
+		t.Errorf("performAction clientID = %v, want %v", args[0], p.clientID)
+	}
+	if args[1] != "TextInputAction.done" {
+		t.Errorf("performAction action = %v, want %q", args[1], "TextInputAction.done")
+	}
+
+	if len(p.word) != 0 {
+		t.Errorf("word = %q, want unchanged (no newline inserted)", string(p.word))
+	}
+}
+
+// TestTextinputKeyEnterMultilineInsertsNewline covers the counterpart: in a
+// multiline field, unmodified Enter inserts '\n' and reports
+// TextInputAction.newline instead of the client's configured inputAction.
+func TestTextinputKeyEnterMultilineInsertsNewline(t *testing.T) {
+	var invoked []invocation
+	p := newTestTextinputPlugin(&invoked)
+	p.inputType = "TextInputType.multiline"
+
+	p.glfwKeyCallback(nil, glfw.KeyEnter, 36, glfw.Press, 0)
+
+	if string(p.word) != "\n" {
+		t.Errorf("word = %q, want \"\\n\"", string(p.word))
+	}
+	if len(invoked) == 0 || invoked[0].method != "TextInputClient.performAction" {
+		t.Fatalf("got invocations %#v, want TextInputClient.performAction first", invoked)
+	}
+	args := invoked[0].args.([]interface{})
+	if args[1] != "TextInputAction.newline" {
+		t.Errorf("performAction action = %v, want %q", args[1], "TextInputAction.newline")
+	}
+}
+
+// TestTextinputSendUpdateEditingStatePayloadShape covers the
+// TextInputClient.updateEditingState payload: [clientID, TextEditingValue],
+// as sent after glfwKeyCallback changes the editing state (here, via the
+// Enter/performAction path, which always reports stateChanged).
+func TestTextinputSendUpdateEditingStatePayloadShape(t *testing.T) {
+	var invoked []invocation
+	p := newTestTextinputPlugin(&invoked)
+	p.word = []rune("hello")
+	p.selectionBase = 5
+	p.selectionExtent = 5
+
+	p.glfwKeyCallback(nil, glfw.KeyEnter, 36, glfw.Press, 0)
+
+	var update *invocation
+	for i := range invoked {
+		if invoked[i].method == "TextInputClient.updateEditingState" {
+			update = &invoked[i]
+		}
+	}
+	if update == nil {
+		t.Fatalf("got invocations %#v, want a TextInputClient.updateEditingState call", invoked)
+	}
+
+	want := []interface{}{
+		p.clientID,
+		argsEditingState{
+			Text:                   "hello",
+			SelectionBase:          5,
+			SelectionExtent:        5,
+			SelectionAffinity:      "TextAffinity.downstream",
+			SelectionIsDirectional: false,
+			ComposingBase:          -1,
+			ComposingExtent:        -1,
+		},
+	}
+	if !reflect.DeepEqual(update.args, want) {
+		t.Errorf("updateEditingState args = %#v, want %#v", update.args, want)
+	}
+}
+
+// TestTextinputPreeditReplacesBackwardSelection is a regression test for the
+// bug fixed in 0f2951c: a backward drag-selection has selectionBase >
+// selectionExtent, and glfwPreeditCallback must still replace exactly the
+// selected span with the preedit text rather than duplicating the
+// [selectionExtent, selectionBase) run around it. glfwPreeditCallback's
+// *glfw.Window parameter is unused, so it can be driven directly with nil.
+func TestTextinputPreeditReplacesBackwardSelection(t *testing.T) {
+	var invoked []invocation
+	p := newTestTextinputPlugin(&invoked)
+	p.word = []rune("hello world")
+	// A backward selection of "world" (offsets 6..11): dragged from 11 back
+	// to 6, so selectionBase (11) > selectionExtent (6).
+	p.selectionBase = 11
+	p.selectionExtent = 6
+
+	p.glfwPreeditCallback(nil, "abc", 1, []int{3}, 0, 3)
+
+	if got, want := string(p.word), "hello abc"; got != want {
+		t.Errorf("word = %q, want %q", got, want)
+	}
+	if p.composingBase != 6 || p.composingExtent != 9 {
+		t.Errorf("composing range = [%d, %d), want [6, 9)", p.composingBase, p.composingExtent)
+	}
+}